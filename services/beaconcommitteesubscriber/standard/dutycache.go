@@ -0,0 +1,120 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+)
+
+// slotsPerEpoch is the number of slots in an epoch on mainnet-configured networks.
+const slotsPerEpoch = 32
+
+// maxDependentRootLookback bounds how far back we will walk looking for a non-skipped slot when
+// calculating a dependent root, so that a long run of skipped slots cannot turn this into an
+// unbounded scan.
+const maxDependentRootLookback = 2048
+
+// dutyCacheEntry holds the attester duties fetched for an epoch, tagged with the dependent root
+// that was in effect when they were fetched. If the dependent root changes - because of a reorg
+// around the epoch boundary - the cached duties are stale and must be refetched.
+type dutyCacheEntry struct {
+	dependentRoot  phase0.Root
+	attesterDuties []*eth2client.AttesterDuty
+}
+
+// dependentRoot calculates the dependent root for attester duties in the given epoch: the block
+// root at slot epoch*SLOTS_PER_EPOCH-1. If that slot was skipped it walks backwards, slot by
+// slot, until it finds a block, capped at maxDependentRootLookback iterations.
+func (s *Service) dependentRoot(ctx context.Context, epoch uint64) (phase0.Root, error) {
+	if epoch == 0 {
+		return s.beaconBlockRoot(ctx, "genesis")
+	}
+
+	slot := epoch*slotsPerEpoch - 1
+	for attempts := 0; attempts < maxDependentRootLookback; attempts++ {
+		root, err := s.beaconBlockRoot(ctx, fmt.Sprintf("%d", slot))
+		if err == nil {
+			return root, nil
+		}
+		if slot == 0 {
+			break
+		}
+		slot--
+	}
+
+	return phase0.Root{}, errors.New("failed to find dependent root within lookback window")
+}
+
+// beaconBlockRoot is a thin wrapper around the beacon block root provider so that callers do not
+// need to deal with its pointer return value.
+func (s *Service) beaconBlockRoot(ctx context.Context, stateID string) (phase0.Root, error) {
+	root, err := s.beaconBlockRootProvider.BeaconBlockRoot(ctx, stateID)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+	if root == nil {
+		return phase0.Root{}, errors.New("no beacon block root returned")
+	}
+
+	return *root, nil
+}
+
+// cachedAttesterDuties returns the attester duties for an epoch, reusing the cached value if the
+// dependent root is unchanged from the last time it was fetched. It reports hits, misses and
+// reorgs to the monitor so that operators can see how effective the cache is.
+func (s *Service) cachedAttesterDuties(ctx context.Context, epoch uint64, idProviders []eth2client.ValidatorIDProvider) ([]*eth2client.AttesterDuty, error) {
+	dependentRoot, rootErr := s.dependentRoot(ctx, epoch)
+
+	s.dutyCacheMu.RLock()
+	cached, exists := s.dutyCache[epoch]
+	s.dutyCacheMu.RUnlock()
+
+	if rootErr == nil && exists && cached.dependentRoot == dependentRoot {
+		s.monitor.DutyCacheHit()
+		return cached.attesterDuties, nil
+	}
+
+	if rootErr == nil && exists && cached.dependentRoot != dependentRoot {
+		log.Debug().Uint64("epoch", epoch).Msg("Dependent root changed; re-orged since last fetch")
+		s.monitor.DutyCacheReorg()
+	}
+	s.monitor.DutyCacheMiss()
+
+	attesterDuties, err := s.attesterDutiesProvider.AttesterDuties(ctx, epoch, idProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	if rootErr == nil {
+		s.dutyCacheMu.Lock()
+		s.dutyCache[epoch] = &dutyCacheEntry{dependentRoot: dependentRoot, attesterDuties: attesterDuties}
+		// Duties for old epochs are never looked up again; drop them so the cache does not grow
+		// without bound across a long-running process.
+		for cachedEpoch := range s.dutyCache {
+			if cachedEpoch+2 < epoch {
+				delete(s.dutyCache, cachedEpoch)
+			}
+		}
+		s.dutyCacheMu.Unlock()
+	} else {
+		log.Warn().Err(rootErr).Uint64("epoch", epoch).Msg("Failed to calculate dependent root; not caching duties")
+	}
+
+	return attesterDuties, nil
+}
@@ -0,0 +1,82 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBeaconBlockRootProvider returns a canned root for a set of "populated" slots and an error
+// for every other (skipped) slot, so dependentRoot's walk-back can be exercised.
+type fakeBeaconBlockRootProvider struct {
+	roots map[string]phase0.Root
+}
+
+func (f *fakeBeaconBlockRootProvider) BeaconBlockRoot(_ context.Context, stateID string) (*phase0.Root, error) {
+	root, exists := f.roots[stateID]
+	if !exists {
+		return nil, errors.New("slot not found (skipped)")
+	}
+	return &root, nil
+}
+
+// TestDependentRootWalksBackOverSkippedSlots confirms that a skipped boundary slot does not fail
+// the dependent root lookup outright; it should walk back to the last non-skipped slot.
+func TestDependentRootWalksBackOverSkippedSlots(t *testing.T) {
+	want := phase0.Root{0x02}
+	epoch := uint64(10)
+	boundarySlot := epoch*slotsPerEpoch - 1
+
+	s := &Service{
+		beaconBlockRootProvider: &fakeBeaconBlockRootProvider{roots: map[string]phase0.Root{
+			fmt.Sprintf("%d", boundarySlot-2): want,
+		}},
+	}
+
+	got, err := s.dependentRoot(context.Background(), epoch)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+// TestDependentRootLookbackExhausted confirms the walk-back gives up with an error rather than
+// scanning forever when no non-skipped slot is found.
+func TestDependentRootLookbackExhausted(t *testing.T) {
+	s := &Service{
+		beaconBlockRootProvider: &fakeBeaconBlockRootProvider{roots: map[string]phase0.Root{}},
+	}
+
+	_, err := s.dependentRoot(context.Background(), uint64(maxDependentRootLookback+10))
+	require.Error(t, err)
+}
+
+// TestDependentRootGenesis confirms epoch 0 resolves against the genesis state rather than
+// walking back from a negative slot.
+func TestDependentRootGenesis(t *testing.T) {
+	want := phase0.Root{0x03}
+	s := &Service{
+		beaconBlockRootProvider: &fakeBeaconBlockRootProvider{roots: map[string]phase0.Root{
+			"genesis": want,
+		}},
+	}
+
+	got, err := s.dependentRoot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
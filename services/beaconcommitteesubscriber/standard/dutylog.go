@@ -0,0 +1,186 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/vouch/services/accountmanager"
+	"github.com/attestantio/vouch/services/attester"
+	"github.com/attestantio/vouch/services/beaconcommitteesubscriber"
+)
+
+// dutyLogGroup collects every validator that shares the same (slot, committee index) tuple, so
+// operators running thousands of validators get one entry per group rather than one per
+// validator in the once-per-epoch summary.
+type dutyLogGroup struct {
+	Slot           uint64   `json:"slot"`
+	CommitteeIndex uint64   `json:"committee_index"`
+	Validators     []string `json:"validators"`
+	Aggregators    []string `json:"aggregators,omitempty"`
+}
+
+// dutyLogProposerSlot is a single upcoming proposer slot in the once-per-epoch summary.
+type dutyLogProposerSlot struct {
+	Slot      uint64 `json:"slot"`
+	Validator string `json:"validator"`
+}
+
+// proposerDutiesForSummary fetches the proposer duties for an epoch, for inclusion in the duty
+// summary. The proposer duties provider is optional; if it is not set the summary simply omits
+// proposer slots.
+func (s *Service) proposerDutiesForSummary(ctx context.Context,
+	epoch uint64,
+	idProviders []eth2client.ValidatorIDProvider,
+) ([]*eth2client.ProposerDuty, error) {
+	if s.proposerDutiesProvider == nil {
+		return nil, nil
+	}
+
+	return s.proposerDutiesProvider.ProposerDuties(ctx, epoch, idProviders)
+}
+
+// logDutySummary emits a single log line, at dutyLogLevel and in dutyLogFormat, summarising the
+// duties just calculated for an epoch: the number of validating accounts, their next attestation
+// slot, which of them are aggregating, and their upcoming proposer slots. It replaces the
+// per-duty TRACE lines with something greppable once per epoch. It logs through s.dutyLog, a
+// logger floored at dutyLogLevel rather than the module-wide log level, so the summary is not
+// silently dropped when the service is run quieter than the summary itself.
+func (s *Service) logDutySummary(ctx context.Context,
+	epoch uint64,
+	accounts []accountmanager.ValidatingAccount,
+	duties []*attester.Duty,
+	subscriptionInfo map[uint64]map[uint64]*beaconcommitteesubscriber.Subscription,
+	proposerDuties []*eth2client.ProposerDuty,
+) {
+	pubKeys := truncatedPubKeysByIndex(ctx, accounts)
+
+	groups := make(map[[2]uint64]*dutyLogGroup)
+	for _, duty := range duties {
+		for i := range duty.ValidatorIndices() {
+			slot := duty.Slot()
+			committeeIndex := duty.CommitteeIndices()[i]
+			validatorIndex := duty.ValidatorIndices()[i]
+
+			key := [2]uint64{slot, committeeIndex}
+			group, exists := groups[key]
+			if !exists {
+				group = &dutyLogGroup{Slot: slot, CommitteeIndex: committeeIndex}
+				groups[key] = group
+			}
+
+			pubKey := pubKeys[validatorIndex]
+			group.Validators = append(group.Validators, pubKey)
+			if sub, exists := subscriptionInfo[slot][committeeIndex]; exists && sub.Aggregate && sub.ValidatorIndex == validatorIndex {
+				group.Aggregators = append(group.Aggregators, pubKey)
+			}
+		}
+	}
+
+	entries := make([]*dutyLogGroup, 0, len(groups))
+	for _, group := range groups {
+		entries = append(entries, group)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Slot != entries[j].Slot {
+			return entries[i].Slot < entries[j].Slot
+		}
+		return entries[i].CommitteeIndex < entries[j].CommitteeIndex
+	})
+
+	proposerSlots := make([]*dutyLogProposerSlot, 0, len(proposerDuties))
+	for _, duty := range proposerDuties {
+		proposerSlots = append(proposerSlots, &dutyLogProposerSlot{
+			Slot:      duty.Slot(),
+			Validator: pubKeys[duty.ValidatorIndex()],
+		})
+	}
+	sort.Slice(proposerSlots, func(i, j int) bool {
+		return proposerSlots[i].Slot < proposerSlots[j].Slot
+	})
+
+	event := s.dutyLog.WithLevel(s.dutyLogLevel).Uint64("epoch", epoch).Int("validating_accounts", len(accounts))
+
+	if s.dutyLogFormat == "json" {
+		data, err := json.Marshal(struct {
+			Attestations   []*dutyLogGroup        `json:"attestations"`
+			ProposerDuties []*dutyLogProposerSlot `json:"proposer_duties,omitempty"`
+		}{
+			Attestations:   entries,
+			ProposerDuties: proposerSlots,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal duty summary")
+			return
+		}
+		event.RawJSON("duties", data).Msg("Epoch duty summary")
+		return
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		line := fmt.Sprintf("slot %d/committee %d: %s", entry.Slot, entry.CommitteeIndex, strings.Join(entry.Validators, ","))
+		if len(entry.Aggregators) > 0 {
+			line += fmt.Sprintf(" (aggregating: %s)", strings.Join(entry.Aggregators, ","))
+		}
+		lines = append(lines, line)
+	}
+	if len(proposerSlots) > 0 {
+		proposing := make([]string, len(proposerSlots))
+		for i, slot := range proposerSlots {
+			proposing[i] = fmt.Sprintf("slot %d: %s", slot.Slot, slot.Validator)
+		}
+		lines = append(lines, fmt.Sprintf("proposing: %s", strings.Join(proposing, ",")))
+	}
+	event.Msg(strings.Join(lines, "; "))
+}
+
+// truncatedPubKeysByIndex builds a validator index to truncated public key lookup, so the duty
+// summary can identify validators without printing their full (96-character) public keys.
+func truncatedPubKeysByIndex(ctx context.Context, accounts []accountmanager.ValidatingAccount) map[uint64]string {
+	pubKeys := make(map[uint64]string, len(accounts))
+	for _, account := range accounts {
+		idProvider, ok := account.(eth2client.ValidatorIDProvider)
+		if !ok {
+			continue
+		}
+		index, err := idProvider.Index(ctx)
+		if err != nil {
+			continue
+		}
+		pubKey, err := account.PubKey(ctx)
+		if err != nil {
+			continue
+		}
+		pubKeys[index] = truncatePubKey(pubKey)
+	}
+
+	return pubKeys
+}
+
+// truncatePubKey shortens a public key's hex representation to its first few characters, enough
+// to distinguish validators in a log line without the noise of the full 96-character key.
+func truncatePubKey(pubKey fmt.Stringer) string {
+	hex := pubKey.String()
+	if len(hex) > 10 {
+		hex = hex[:10]
+	}
+
+	return hex + "…"
+}
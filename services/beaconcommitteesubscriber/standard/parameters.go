@@ -0,0 +1,161 @@
+// Copyright © 2020, 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/vouch/services/attestationaggregator"
+	"github.com/attestantio/vouch/services/metrics"
+	"github.com/attestantio/vouch/services/submitter"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type parameters struct {
+	logLevel                 zerolog.Level
+	monitor                  metrics.BeaconCommitteeSubscriptionMonitor
+	processConcurrency       int64
+	attesterDutiesProvider   eth2client.AttesterDutiesProvider
+	proposerDutiesProvider   eth2client.ProposerDutiesProvider
+	beaconBlockRootProvider  eth2client.BeaconBlockRootProvider
+	attestationAggregator    attestationaggregator.Service
+	beaconCommitteeSubmitter submitter.BeaconCommitteeSubscriptionsSubmitter
+	dutyLogLevel             zerolog.Level
+	dutyLogFormat            string
+}
+
+// Parameter is the interface for service parameters.
+type Parameter interface {
+	apply(*parameters)
+}
+
+type parameterFunc func(*parameters)
+
+func (f parameterFunc) apply(p *parameters) {
+	f(p)
+}
+
+// WithLogLevel sets the log level for the module.
+func WithLogLevel(logLevel zerolog.Level) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.logLevel = logLevel
+	})
+}
+
+// WithMonitor sets the monitor for the module.
+func WithMonitor(monitor metrics.BeaconCommitteeSubscriptionMonitor) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.monitor = monitor
+	})
+}
+
+// WithProcessConcurrency sets the concurrency for the module.
+func WithProcessConcurrency(concurrency int64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.processConcurrency = concurrency
+	})
+}
+
+// WithAttesterDutiesProvider sets the attester duties provider for the module.
+func WithAttesterDutiesProvider(provider eth2client.AttesterDutiesProvider) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.attesterDutiesProvider = provider
+	})
+}
+
+// WithProposerDutiesProvider sets the proposer duties provider for the module, used to include
+// validators' upcoming proposer slots in the once-per-epoch duty summary. Optional: if unset, the
+// summary simply omits proposer slots.
+func WithProposerDutiesProvider(provider eth2client.ProposerDutiesProvider) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.proposerDutiesProvider = provider
+	})
+}
+
+// WithBeaconBlockRootProvider sets the beacon block root provider for the module, used to
+// calculate the dependent root for attester duty cache invalidation.
+func WithBeaconBlockRootProvider(provider eth2client.BeaconBlockRootProvider) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.beaconBlockRootProvider = provider
+	})
+}
+
+// WithAttestationAggregator sets the attestation aggregator for the module.
+func WithAttestationAggregator(aggregator attestationaggregator.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.attestationAggregator = aggregator
+	})
+}
+
+// WithBeaconCommitteeSubmitter sets the beacon committee subscription submitter for the module.
+func WithBeaconCommitteeSubmitter(submitter submitter.BeaconCommitteeSubscriptionsSubmitter) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.beaconCommitteeSubmitter = submitter
+	})
+}
+
+// WithDutyLogLevel sets the level at which the once-per-epoch duty summary is logged.
+func WithDutyLogLevel(level zerolog.Level) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.dutyLogLevel = level
+	})
+}
+
+// WithDutyLogFormat sets the format of the once-per-epoch duty summary: "text" for a single
+// compact, greppable line with one entry per committee, or "json" for a JSON list suitable for
+// log aggregators.
+func WithDutyLogFormat(format string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.dutyLogFormat = format
+	})
+}
+
+// parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
+func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
+	parameters := parameters{
+		logLevel:           zerolog.GlobalLevel(),
+		processConcurrency: 1,
+		dutyLogLevel:       zerolog.InfoLevel,
+		dutyLogFormat:      "text",
+	}
+	for _, p := range params {
+		if params != nil {
+			p.apply(&parameters)
+		}
+	}
+
+	if parameters.monitor == nil {
+		return nil, errors.New("no monitor specified")
+	}
+	if parameters.processConcurrency == 0 {
+		return nil, errors.New("no process concurrency specified")
+	}
+	if parameters.attesterDutiesProvider == nil {
+		return nil, errors.New("no attester duties provider specified")
+	}
+	if parameters.beaconBlockRootProvider == nil {
+		return nil, errors.New("no beacon block root provider specified")
+	}
+	if parameters.attestationAggregator == nil {
+		return nil, errors.New("no attestation aggregator specified")
+	}
+	if parameters.beaconCommitteeSubmitter == nil {
+		return nil, errors.New("no beacon committee submitter specified")
+	}
+	if parameters.dutyLogFormat != "text" && parameters.dutyLogFormat != "json" {
+		return nil, errors.New("duty log format must be 'text' or 'json'")
+	}
+
+	return &parameters, nil
+}
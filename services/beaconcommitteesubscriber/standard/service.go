@@ -34,11 +34,19 @@ import (
 
 // Service is an beacon committee subscriber.
 type Service struct {
-	monitor                metrics.BeaconCommitteeSubscriptionMonitor
-	processConcurrency     int64
-	attesterDutiesProvider eth2client.AttesterDutiesProvider
-	attestationAggregator  attestationaggregator.Service
-	submitter              submitter.BeaconCommitteeSubscriptionsSubmitter
+	monitor                 metrics.BeaconCommitteeSubscriptionMonitor
+	processConcurrency      int64
+	attesterDutiesProvider  eth2client.AttesterDutiesProvider
+	proposerDutiesProvider  eth2client.ProposerDutiesProvider
+	beaconBlockRootProvider eth2client.BeaconBlockRootProvider
+	attestationAggregator   attestationaggregator.Service
+	submitter               submitter.BeaconCommitteeSubscriptionsSubmitter
+	dutyLog                 zerolog.Logger
+	dutyLogLevel            zerolog.Level
+	dutyLogFormat           string
+
+	dutyCacheMu deadlock.RWMutex
+	dutyCache   map[uint64]*dutyCacheEntry
 }
 
 // module-wide log.
@@ -57,12 +65,25 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		log = log.Level(parameters.logLevel)
 	}
 
+	// The duty summary has its own logger, floored at dutyLogLevel rather than the module-wide
+	// logLevel, so that it can still be emitted when the rest of the service is run quieter (for
+	// example logLevel=warn with dutyLogLevel=info to get one digest per epoch with no other
+	// per-duty noise).
+	dutyLog := zerologger.With().Str("service", "beaconcommitteesubscriber").Str("impl", "standard").Logger().
+		Level(parameters.dutyLogLevel)
+
 	s := &Service{
-		processConcurrency:     parameters.processConcurrency,
-		monitor:                parameters.monitor,
-		attesterDutiesProvider: parameters.attesterDutiesProvider,
-		attestationAggregator:  parameters.attestationAggregator,
-		submitter:              parameters.beaconCommitteeSubmitter,
+		processConcurrency:      parameters.processConcurrency,
+		monitor:                 parameters.monitor,
+		attesterDutiesProvider:  parameters.attesterDutiesProvider,
+		proposerDutiesProvider:  parameters.proposerDutiesProvider,
+		beaconBlockRootProvider: parameters.beaconBlockRootProvider,
+		attestationAggregator:   parameters.attestationAggregator,
+		submitter:               parameters.beaconCommitteeSubmitter,
+		dutyLog:                 dutyLog,
+		dutyLogLevel:            parameters.dutyLogLevel,
+		dutyLogFormat:           parameters.dutyLogFormat,
+		dutyCache:               make(map[uint64]*dutyCacheEntry),
 	}
 
 	return s, nil
@@ -80,7 +101,7 @@ func (s *Service) Subscribe(ctx context.Context, epoch uint64, accounts []accoun
 	for i, account := range accounts {
 		idProviders[i] = account.(eth2client.ValidatorIDProvider)
 	}
-	attesterDuties, err := s.attesterDutiesProvider.AttesterDuties(ctx, epoch, idProviders)
+	attesterDuties, err := s.cachedAttesterDuties(ctx, epoch, idProviders)
 	if err != nil {
 		s.monitor.BeaconCommitteeSubscriptionCompleted(started, "failed")
 		return nil, errors.Wrap(err, "failed to obtain attester duties")
@@ -99,6 +120,13 @@ func (s *Service) Subscribe(ctx context.Context, epoch uint64, accounts []accoun
 	}
 	log.Trace().Dur("elapsed", time.Since(started)).Msg("Calculated subscription info")
 
+	proposerDuties, err := s.proposerDutiesForSummary(ctx, epoch, idProviders)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to obtain proposer duties for duty summary")
+	}
+
+	s.logDutySummary(ctx, epoch, accounts, duties, subscriptionInfo, proposerDuties)
+
 	// Update metrics.
 	subscriptions := 0
 	aggregators := 0
@@ -0,0 +1,48 @@
+// Copyright © 2020, 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines the monitor interfaces that strategies and services report their
+// Prometheus counters through, so that each package can depend on the narrow interface it needs
+// rather than a single monolithic monitor.
+package metrics
+
+import "time"
+
+// ClientMonitor tracks the outcome and latency of individual beacon node operations.
+type ClientMonitor interface {
+	// ClientOperation reports the result and duration of an operation carried out against a
+	// named beacon node client.
+	ClientOperation(provider string, operation string, succeeded bool, duration time.Duration)
+}
+
+// BeaconCommitteeSubscriptionMonitor tracks beacon committee subscription activity, including
+// the effectiveness of the attester duty cache.
+type BeaconCommitteeSubscriptionMonitor interface {
+	// BeaconCommitteeSubscriptionCompleted reports the completion of a beacon committee
+	// subscription request, tagged with how long it took and its result.
+	BeaconCommitteeSubscriptionCompleted(started time.Time, result string)
+	// BeaconCommitteeSubscribers reports the number of beacon committee subscriptions made.
+	BeaconCommitteeSubscribers(subscribers int)
+	// BeaconCommitteeAggregators reports the number of beacon committee subscriptions made as
+	// an aggregator.
+	BeaconCommitteeAggregators(aggregators int)
+	// DutyCacheHit reports that cached attester duties were reused because the dependent root
+	// for the epoch had not changed.
+	DutyCacheHit()
+	// DutyCacheMiss reports that attester duties had to be fetched because no usable cache
+	// entry existed for the epoch.
+	DutyCacheMiss()
+	// DutyCacheReorg reports that cached attester duties were discarded because the dependent
+	// root for the epoch changed since they were fetched.
+	DutyCacheReorg()
+}
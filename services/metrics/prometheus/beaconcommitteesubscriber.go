@@ -0,0 +1,119 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prometheus provides Prometheus-backed implementations of the metrics package's monitor
+// interfaces, registering the counters and gauges those interfaces report through.
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Service is a Prometheus-backed implementation of metrics.BeaconCommitteeSubscriptionMonitor.
+type Service struct {
+	subscriptionsCompleted *prometheus.CounterVec
+	subscriptionDuration   *prometheus.HistogramVec
+	subscribers            prometheus.Gauge
+	aggregators            prometheus.Gauge
+	dutyCacheHits          prometheus.Counter
+	dutyCacheMisses        prometheus.Counter
+	dutyCacheReorgs        prometheus.Counter
+}
+
+// New creates a new Prometheus-backed beacon committee subscription monitor, registering its
+// counters and gauges with the default registry.
+func New() *Service {
+	return &Service{
+		subscriptionsCompleted: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "subscriptions_completed_total",
+			Help:      "The number of beacon committee subscription requests completed, by result.",
+		}, []string{"result"}),
+		subscriptionDuration: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "subscription_duration_seconds",
+			Help:      "The time taken to complete a beacon committee subscription request, by result.",
+		}, []string{"result"}),
+		subscribers: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "subscribers",
+			Help:      "The number of beacon committee subscriptions currently made.",
+		}),
+		aggregators: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "aggregators",
+			Help:      "The number of beacon committee subscriptions currently made as an aggregator.",
+		}),
+		dutyCacheHits: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "duty_cache_hits_total",
+			Help:      "The number of times cached attester duties were reused because the dependent root for the epoch had not changed.",
+		}),
+		dutyCacheMisses: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "duty_cache_misses_total",
+			Help:      "The number of times attester duties had to be fetched because no usable cache entry existed for the epoch.",
+		}),
+		dutyCacheReorgs: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "vouch",
+			Subsystem: "beaconcommitteesubscriber",
+			Name:      "duty_cache_reorgs_total",
+			Help:      "The number of times cached attester duties were discarded because the dependent root for the epoch changed since they were fetched.",
+		}),
+	}
+}
+
+// BeaconCommitteeSubscriptionCompleted reports the completion of a beacon committee subscription
+// request, tagged with how long it took and its result.
+func (s *Service) BeaconCommitteeSubscriptionCompleted(started time.Time, result string) {
+	s.subscriptionsCompleted.WithLabelValues(result).Inc()
+	s.subscriptionDuration.WithLabelValues(result).Observe(time.Since(started).Seconds())
+}
+
+// BeaconCommitteeSubscribers reports the number of beacon committee subscriptions made.
+func (s *Service) BeaconCommitteeSubscribers(subscribers int) {
+	s.subscribers.Set(float64(subscribers))
+}
+
+// BeaconCommitteeAggregators reports the number of beacon committee subscriptions made as an
+// aggregator.
+func (s *Service) BeaconCommitteeAggregators(aggregators int) {
+	s.aggregators.Set(float64(aggregators))
+}
+
+// DutyCacheHit reports that cached attester duties were reused because the dependent root for
+// the epoch had not changed.
+func (s *Service) DutyCacheHit() {
+	s.dutyCacheHits.Inc()
+}
+
+// DutyCacheMiss reports that attester duties had to be fetched because no usable cache entry
+// existed for the epoch.
+func (s *Service) DutyCacheMiss() {
+	s.dutyCacheMisses.Inc()
+}
+
+// DutyCacheReorg reports that cached attester duties were discarded because the dependent root
+// for the epoch changed since they were fetched.
+func (s *Service) DutyCacheReorg() {
+	s.dutyCacheReorgs.Inc()
+}
@@ -0,0 +1,136 @@
+// Copyright © 2020, 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package best
+
+import (
+	"time"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/vouch/services/metrics"
+	"github.com/attestantio/vouch/strategies/beaconblockproposal/best/scorer"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type parameters struct {
+	logLevel                            zerolog.Level
+	clientMonitor                       metrics.ClientMonitor
+	processConcurrency                  int64
+	beaconBlockProposalProviders        map[string]eth2client.BeaconBlockProposalProvider
+	blindedBeaconBlockProposalProviders map[string]eth2client.BlindedBeaconBlockProposalProvider
+	timeout                             time.Duration
+	minBidDelta                         float64
+	scorer                              scorer.Scorer
+}
+
+// Parameter is the interface for service parameters.
+type Parameter interface {
+	apply(*parameters)
+}
+
+type parameterFunc func(*parameters)
+
+func (f parameterFunc) apply(p *parameters) {
+	f(p)
+}
+
+// WithLogLevel sets the log level for the module.
+func WithLogLevel(logLevel zerolog.Level) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.logLevel = logLevel
+	})
+}
+
+// WithClientMonitor sets the client monitor for the module.
+func WithClientMonitor(clientMonitor metrics.ClientMonitor) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.clientMonitor = clientMonitor
+	})
+}
+
+// WithProcessConcurrency sets the concurrency for the module.
+func WithProcessConcurrency(concurrency int64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.processConcurrency = concurrency
+	})
+}
+
+// WithTimeout sets the timeout for the module.
+func WithTimeout(timeout time.Duration) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.timeout = timeout
+	})
+}
+
+// WithBeaconBlockProposalProviders sets the beacon block proposal providers.
+func WithBeaconBlockProposalProviders(providers map[string]eth2client.BeaconBlockProposalProvider) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.beaconBlockProposalProviders = providers
+	})
+}
+
+// WithBlindedBeaconBlockProposalProviders sets the blinded beacon block proposal providers,
+// used to obtain proposals from MEV builders.
+func WithBlindedBeaconBlockProposalProviders(providers map[string]eth2client.BlindedBeaconBlockProposalProvider) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.blindedBeaconBlockProposalProviders = providers
+	})
+}
+
+// WithMinBidDelta sets the minimum score improvement a blinded builder bid must have over the
+// best local full block before it is preferred. A zero delta means any higher-scoring bid wins.
+func WithMinBidDelta(delta float64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.minBidDelta = delta
+	})
+}
+
+// WithScorer sets the scoring backend used to rank beacon block proposals. Defaults to the
+// naive flat heuristic; pass a reward-aware scorer to A/B against it.
+func WithScorer(scorer scorer.Scorer) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.scorer = scorer
+	})
+}
+
+// parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
+func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
+	parameters := parameters{
+		logLevel:           zerolog.GlobalLevel(),
+		processConcurrency: 1,
+		timeout:            2 * time.Second,
+		minBidDelta:        0,
+		scorer:             scorer.NewNaive(),
+	}
+	for _, p := range params {
+		if params != nil {
+			p.apply(&parameters)
+		}
+	}
+
+	if parameters.clientMonitor == nil {
+		return nil, errors.New("no client monitor specified")
+	}
+	if parameters.processConcurrency == 0 {
+		return nil, errors.New("no process concurrency specified")
+	}
+	if len(parameters.beaconBlockProposalProviders) == 0 {
+		return nil, errors.New("no beacon block proposal providers specified")
+	}
+	if parameters.scorer == nil {
+		return nil, errors.New("no scorer specified")
+	}
+
+	return &parameters, nil
+}
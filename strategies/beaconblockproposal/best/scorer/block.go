@@ -0,0 +1,53 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorer
+
+import (
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// VersionedBeaconBlock carries a beacon block proposal from any fork. Only the field matching
+// Version is populated; the rest are nil. This lets a Scorer take a fork-aware path without the
+// rest of the service needing to know about individual fork body layouts.
+type VersionedBeaconBlock struct {
+	Version   consensusspec.DataVersion
+	Phase0    *phase0.BeaconBlock
+	Altair    *altair.BeaconBlock
+	Bellatrix *bellatrix.BeaconBlock
+	Capella   *capella.BeaconBlock
+	Deneb     *deneb.BeaconBlock
+	Electra   *electra.BeaconBlock
+}
+
+// NewVersionedBeaconBlock converts a versioned beacon block proposal, as returned by a beacon
+// node's proposal provider, into the scorer's own VersionedBeaconBlock. Callers that only have a
+// single fork's block populated (for example a blinded proposal converted back to full-block
+// shape) can construct a VersionedBeaconBlock directly instead.
+func NewVersionedBeaconBlock(proposal *consensusspec.VersionedBeaconBlock) *VersionedBeaconBlock {
+	return &VersionedBeaconBlock{
+		Version:   proposal.Version,
+		Phase0:    proposal.Phase0,
+		Altair:    proposal.Altair,
+		Bellatrix: proposal.Bellatrix,
+		Capella:   proposal.Capella,
+		Deneb:     proposal.Deneb,
+		Electra:   proposal.Electra,
+	}
+}
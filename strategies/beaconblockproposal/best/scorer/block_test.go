@@ -0,0 +1,65 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorer_test
+
+import (
+	"testing"
+
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/attestantio/vouch/strategies/beaconblockproposal/best/scorer"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewVersionedBeaconBlockCapellaDeneb confirms that NewVersionedBeaconBlock carries the
+// Capella and Deneb blocks through, rather than leaving a scorer to fall through to a nil
+// Phase0 block for these versions.
+func TestNewVersionedBeaconBlockCapellaDeneb(t *testing.T) {
+	tests := []struct {
+		name     string
+		proposal *consensusspec.VersionedBeaconBlock
+	}{
+		{
+			name: "Capella",
+			proposal: &consensusspec.VersionedBeaconBlock{
+				Version: consensusspec.DataVersionCapella,
+				Capella: &capella.BeaconBlock{Slot: phase0.Slot(123)},
+			},
+		},
+		{
+			name: "Deneb",
+			proposal: &consensusspec.VersionedBeaconBlock{
+				Version: consensusspec.DataVersionDeneb,
+				Deneb:   &deneb.BeaconBlock{Slot: phase0.Slot(456)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block := scorer.NewVersionedBeaconBlock(test.proposal)
+			require.Equal(t, test.proposal.Version, block.Version)
+			switch test.proposal.Version {
+			case consensusspec.DataVersionCapella:
+				require.NotNil(t, block.Capella)
+				require.Equal(t, test.proposal.Capella.Slot, block.Capella.Slot)
+			case consensusspec.DataVersionDeneb:
+				require.NotNil(t, block.Deneb)
+				require.Equal(t, test.proposal.Deneb.Slot, block.Deneb.Slot)
+			}
+		})
+	}
+}
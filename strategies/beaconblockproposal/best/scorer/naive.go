@@ -0,0 +1,353 @@
+// Copyright © 2020, 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorer
+
+import (
+	"context"
+	"fmt"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	zerologger "github.com/rs/zerolog/log"
+)
+
+// module-wide log.
+var log = zerologger.With().Str("strategy", "beaconblockproposal").Str("impl", "best").Str("scorer", "naive").Logger()
+
+// syncCommitteeSize is the number of bits in a sync aggregate's SyncCommitteeBits.
+const syncCommitteeSize = 512
+
+// defaultSyncCommitteeWeight is the per-bit weight applied to a post-Altair block's sync
+// aggregate participation. Individual attestation reward at 16K validators is around 90,000
+// GWei; a full sync committee contribution is worth roughly the same as 350 attestations, so we
+// divide that across the 512 committee bits to get a per-bit weight.
+const defaultSyncCommitteeWeight = float64(350) / float64(syncCommitteeSize)
+
+// Naive scores a beacon block proposal using a flat 1/inclusion_distance heuristic per
+// attestation, with a fixed weight for slashings and, from Altair onwards, a fixed weight per
+// sync committee bit set. It does not consider effective balance or reward-flag decay; use
+// RewardAware for that. Neither scorer tracks validators' previous-epoch participation.
+type Naive struct {
+	syncCommitteeWeight float64
+	committeesProvider  eth2client.BeaconCommitteesProvider
+}
+
+// NaiveOption configures a Naive scorer.
+type NaiveOption interface {
+	apply(*Naive)
+}
+
+type naiveOptionFunc func(*Naive)
+
+func (f naiveOptionFunc) apply(n *Naive) {
+	f(n)
+}
+
+// WithSyncCommitteeWeight sets the per-bit weight applied to a post-Altair block's sync
+// aggregate participation. Defaults to a value that makes a full sync committee contribution
+// worth about as much as 350 attestations.
+func WithSyncCommitteeWeight(weight float64) NaiveOption {
+	return naiveOptionFunc(func(n *Naive) {
+		n.syncCommitteeWeight = weight
+	})
+}
+
+// WithCommitteesProvider sets the beacon committees provider used to split an Electra
+// attestation's concatenated AggregationBits back into its per-committee sub-ranges (needed to
+// deduplicate validator participation across attestations whose committee sets only partially
+// overlap). Without it, Electra attestations fall back to deduplicating only against other
+// attestations covering the exact same set of committees.
+func WithCommitteesProvider(provider eth2client.BeaconCommitteesProvider) NaiveOption {
+	return naiveOptionFunc(func(n *Naive) {
+		n.committeesProvider = provider
+	})
+}
+
+// NewNaive creates a new naive scorer.
+func NewNaive(opts ...NaiveOption) *Naive {
+	n := &Naive{
+		syncCommitteeWeight: defaultSyncCommitteeWeight,
+	}
+	for _, opt := range opts {
+		opt.apply(n)
+	}
+
+	return n
+}
+
+// Score generates a score for a beacon block.
+// The score is relative to the reward expected by proposing the block. It picks a fork-aware
+// code path based on the block's version, since Electra changed the attestation format to carry
+// aggregation bits across multiple committees in a single Attestation (EIP-7549), and Altair
+// introduced the sync committee, scored separately as it carries no inclusion distance.
+func (n *Naive) Score(ctx context.Context, name string, slot uint64, block *VersionedBeaconBlock) float64 {
+	switch block.Version {
+	case consensusspec.DataVersionElectra:
+		body := block.Electra.Body
+		return n.scoreElectraBeaconBlockProposal(ctx, name, slot, block.Electra) + n.scoreSyncAggregate(name, slot, body.SyncAggregate)
+	case consensusspec.DataVersionDeneb:
+		body := block.Deneb.Body
+		return scorePhase0StyleBeaconBlockProposal(name, slot, body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)) +
+			n.scoreSyncAggregate(name, slot, body.SyncAggregate)
+	case consensusspec.DataVersionCapella:
+		body := block.Capella.Body
+		return scorePhase0StyleBeaconBlockProposal(name, slot, body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)) +
+			n.scoreSyncAggregate(name, slot, body.SyncAggregate)
+	case consensusspec.DataVersionBellatrix:
+		body := block.Bellatrix.Body
+		return scorePhase0StyleBeaconBlockProposal(name, slot, body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)) +
+			n.scoreSyncAggregate(name, slot, body.SyncAggregate)
+	case consensusspec.DataVersionAltair:
+		body := block.Altair.Body
+		return scorePhase0StyleBeaconBlockProposal(name, slot, body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)) +
+			n.scoreSyncAggregate(name, slot, body.SyncAggregate)
+	default:
+		body := block.Phase0.Body
+		return scorePhase0StyleBeaconBlockProposal(name, slot, body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings))
+	}
+}
+
+// ScoreBlinded generates a score for a blinded beacon block proposal, on the same basis as
+// Score, so that a builder's bid can be compared directly against a local block's score. Blinded
+// proposals carry the same body shape as a Bellatrix-or-later full block, minus the execution
+// payload, which is not scored in either case.
+func (n *Naive) ScoreBlinded(_ context.Context, name string, slot uint64, block *apiv1.BlindedBeaconBlock) float64 {
+	body := block.Body
+	return scorePhase0StyleBeaconBlockProposal(name, slot, body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)) +
+		n.scoreSyncAggregate(name, slot, body.SyncAggregate)
+}
+
+// scoreSyncAggregate scores a post-Altair block's sync committee participation. Phase0 blocks
+// have no sync aggregate, so callers skip this for that version rather than passing nil.
+func (n *Naive) scoreSyncAggregate(name string, slot uint64, syncAggregate *altair.SyncAggregate) float64 {
+	if syncAggregate == nil {
+		return 0
+	}
+
+	bits := syncAggregate.SyncCommitteeBits.Count()
+	syncScore := float64(bits) * n.syncCommitteeWeight
+
+	log.Trace().
+		Uint64("slot", slot).
+		Str("provider", name).
+		Uint64("sync_committee_bits", bits).
+		Float64("sync_score", syncScore).
+		Msg("Scored sync aggregate")
+
+	return syncScore
+}
+
+// scorePhase0StyleBeaconBlockProposal scores the pre-Electra attestation format, where each
+// Attestation votes for a single committee. Phase0, Altair and Bellatrix all share this format.
+func scorePhase0StyleBeaconBlockProposal(name string, slot uint64, attestations []*phase0.Attestation, numProposerSlashings int, numAttesterSlashings int) float64 {
+	immediateAttestationScore := float64(0)
+	attestationScore := float64(0)
+
+	// Add attestation scores.
+	for _, attestation := range attestations {
+		inclusionDistance := float64(slot - attestation.Data.Slot)
+		attestationScore += float64(attestation.AggregationBits.Count()) / inclusionDistance
+		if inclusionDistance == 1 {
+			immediateAttestationScore += float64(attestation.AggregationBits.Count()) / inclusionDistance
+		}
+	}
+
+	// Add slashing scores.
+	// Slashing reward will be at most MAX_EFFECTIVE_BALANCE/WHISTLEBLOWER_REWARD_QUOTIENT,
+	// which is 0.0625 Ether.
+	// Individual attestation reward at 16K validators will be around 90,000 GWei, or .00009 Ether.
+	// So we state that a single slashing event has the same weight as about 700 attestations.
+	slashingWeight := float64(700)
+
+	// Add proposer slashing scores.
+	proposerSlashingScore := float64(numProposerSlashings) * slashingWeight
+
+	// Add attester slashing scores.
+	attesterSlashingScore := float64(numAttesterSlashings) * slashingWeight
+
+	total := attestationScore + immediateAttestationScore + proposerSlashingScore + attesterSlashingScore
+
+	log.Trace().
+		Uint64("slot", slot).
+		Str("provider", name).
+		Float64("immediate_attestations", immediateAttestationScore).
+		Float64("attestations", attestationScore).
+		Float64("proposer_slashings", proposerSlashingScore).
+		Float64("attester_slashings", attesterSlashingScore).
+		Float64("total", total).
+		Msg("Scored block")
+
+	return total
+}
+
+// scoreElectraBeaconBlockProposal scores the post-Electra attestation format (EIP-7549), where a
+// single Attestation carries one concatenated AggregationBits bitlist across all the committees
+// listed in CommitteeBits, rather than one bitlist per committee. When a committees provider is
+// configured (see WithCommitteesProvider), that concatenated bitlist is split back into its
+// per-committee sub-ranges using each committee's actual size, so that participation is
+// deduplicated per committee across attestations whose committee sets only partially overlap,
+// and per-committee counts can be logged. Without a committees provider, participation is
+// deduplicated only against attestations covering the exact same set of committees, since the
+// bitlist cannot otherwise be split into per-committee ranges.
+func (n *Naive) scoreElectraBeaconBlockProposal(ctx context.Context, name string, slot uint64, block *electra.BeaconBlock) float64 {
+	immediateAttestationScore := float64(0)
+	attestationScore := float64(0)
+
+	// seen tracks, per (attestation slot, committee index), which within-committee bit positions
+	// have already been credited, so a committee shared by two attestations with otherwise
+	// different committee sets is only counted once.
+	seen := make(map[phase0.Slot]map[uint64]map[uint64]bool)
+	// seenByCommitteeSet is the degraded fallback used when committee sizes aren't available:
+	// dedup by the exact set of committees an attestation covers.
+	seenByCommitteeSet := make(map[string]map[uint64]bool)
+	committeeSizesBySlot := make(map[phase0.Slot]map[uint64]int)
+
+	for _, attestation := range block.Body.Attestations {
+		inclusionDistance := float64(slot - attestation.Data.Slot)
+		committeeIndices := attestation.CommitteeBits.BitIndices()
+		bits := attestation.AggregationBits
+
+		sizes, err := n.committeeSizesForSlot(ctx, attestation.Data.Slot, committeeIndices, committeeSizesBySlot)
+		if err != nil {
+			log.Trace().Err(err).Uint64("slot", slot).Str("provider", name).
+				Msg("No committee sizes available for Electra attestation; deduplicating by committee set instead of per committee")
+
+			key := fmt.Sprintf("%v", committeeIndices)
+			seenForCommitteeSet, exists := seenByCommitteeSet[key]
+			if !exists {
+				seenForCommitteeSet = make(map[uint64]bool)
+				seenByCommitteeSet[key] = seenForCommitteeSet
+			}
+
+			newParticipants := float64(0)
+			for i := uint64(0); i < bits.Len(); i++ {
+				if !bits.BitAt(i) || seenForCommitteeSet[i] {
+					continue
+				}
+				seenForCommitteeSet[i] = true
+				newParticipants++
+			}
+			attestationScore += newParticipants / inclusionDistance
+			if inclusionDistance == 1 {
+				immediateAttestationScore += newParticipants / inclusionDistance
+			}
+
+			log.Trace().
+				Uint64("slot", slot).
+				Str("provider", name).
+				Int("committees", len(committeeIndices)).
+				Msg("Scored Electra attestation")
+			continue
+		}
+
+		seenForSlot, exists := seen[attestation.Data.Slot]
+		if !exists {
+			seenForSlot = make(map[uint64]map[uint64]bool)
+			seen[attestation.Data.Slot] = seenForSlot
+		}
+
+		offset := uint64(0)
+		for _, committeeIndex := range committeeIndices {
+			size := uint64(sizes[committeeIndex])
+
+			seenForCommittee, exists := seenForSlot[committeeIndex]
+			if !exists {
+				seenForCommittee = make(map[uint64]bool)
+				seenForSlot[committeeIndex] = seenForCommittee
+			}
+
+			newParticipants := float64(0)
+			committeeBits := 0
+			for i := uint64(0); i < size; i++ {
+				if !bits.BitAt(offset + i) {
+					continue
+				}
+				committeeBits++
+				if seenForCommittee[i] {
+					continue
+				}
+				seenForCommittee[i] = true
+				newParticipants++
+			}
+			offset += size
+
+			attestationScore += newParticipants / inclusionDistance
+			if inclusionDistance == 1 {
+				immediateAttestationScore += newParticipants / inclusionDistance
+			}
+
+			log.Trace().
+				Uint64("slot", slot).
+				Str("provider", name).
+				Uint64("committee_index", committeeIndex).
+				Int("committee_bits", committeeBits).
+				Msg("Scored Electra committee")
+		}
+	}
+
+	slashingWeight := float64(700)
+	proposerSlashingScore := float64(len(block.Body.ProposerSlashings)) * slashingWeight
+	attesterSlashingScore := float64(len(block.Body.AttesterSlashings)) * slashingWeight
+
+	total := attestationScore + immediateAttestationScore + proposerSlashingScore + attesterSlashingScore
+
+	log.Trace().
+		Uint64("slot", slot).
+		Str("provider", name).
+		Float64("immediate_attestations", immediateAttestationScore).
+		Float64("attestations", attestationScore).
+		Float64("proposer_slashings", proposerSlashingScore).
+		Float64("attester_slashings", attesterSlashingScore).
+		Float64("total", total).
+		Msg("Scored Electra block")
+
+	return total
+}
+
+// committeeSizesForSlot returns the size of every committee for the given attestation slot,
+// keyed by committee index, fetching and caching them for the slot the first time any of its
+// committees is needed. Returns an error if no committees provider is configured.
+func (n *Naive) committeeSizesForSlot(ctx context.Context, slot phase0.Slot, committeeIndices []uint64, cache map[phase0.Slot]map[uint64]int) (map[uint64]int, error) {
+	if sizes, exists := cache[slot]; exists {
+		return sizes, nil
+	}
+
+	if n.committeesProvider == nil {
+		return nil, errors.New("no committees provider configured")
+	}
+
+	committees, err := n.committeesProvider.BeaconCommittees(ctx, fmt.Sprintf("%d", slot))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain beacon committees")
+	}
+
+	sizes := make(map[uint64]int, len(committees))
+	for _, committee := range committees {
+		sizes[uint64(committee.Index)] = len(committee.Validators)
+	}
+	cache[slot] = sizes
+
+	for _, committeeIndex := range committeeIndices {
+		if _, exists := sizes[committeeIndex]; !exists {
+			return nil, errors.Errorf("no committee found for index %d at slot %d", committeeIndex, slot)
+		}
+	}
+
+	return sizes, nil
+}
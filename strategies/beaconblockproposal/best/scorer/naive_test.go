@@ -0,0 +1,138 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorer
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScoreElectraBeaconBlockProposalDedup confirms that two EIP-7549 attestations covering the
+// exact same set of committees only credit each participant once, even though each attestation's
+// AggregationBits is scored independently of the others. No committees provider is configured, so
+// this exercises the degraded, exact-committee-set fallback.
+func TestScoreElectraBeaconBlockProposalDedup(t *testing.T) {
+	committeeBits := bitfield.Bitvector64{}
+	committeeBits.SetBitAt(0, true)
+	committeeBits.SetBitAt(1, true)
+
+	firstBits := bitfield.NewBitlist(4)
+	firstBits.SetBitAt(0, true)
+	firstBits.SetBitAt(1, true)
+
+	// secondBits resubmits the same aggregate (bits 0 and 1 already credited) plus one new
+	// participant at bit 2.
+	secondBits := bitfield.NewBitlist(4)
+	secondBits.SetBitAt(0, true)
+	secondBits.SetBitAt(1, true)
+	secondBits.SetBitAt(2, true)
+
+	block := &electra.BeaconBlock{
+		Body: &electra.BeaconBlockBody{
+			Attestations: []*electra.Attestation{
+				{
+					AggregationBits: firstBits,
+					CommitteeBits:   committeeBits,
+					Data:            &phase0.AttestationData{Slot: phase0.Slot(10)},
+				},
+				{
+					AggregationBits: secondBits,
+					CommitteeBits:   committeeBits,
+					Data:            &phase0.AttestationData{Slot: phase0.Slot(10)},
+				},
+			},
+		},
+	}
+
+	// At slot 11, inclusion distance is 1 for both attestations: 2 new participants from the
+	// first attestation and 1 new (deduplicated) participant from the second give an immediate
+	// and a cumulative attestation score of 3, doubled since both counters are identical here.
+	n := NewNaive()
+	got := n.scoreElectraBeaconBlockProposal(context.Background(), "test", 11, block)
+	require.InDelta(t, 6.0, got, 0.0001)
+}
+
+// fakeCommitteesProvider returns a fixed set of committees for any slot requested.
+type fakeCommitteesProvider struct {
+	committees []*apiv1.BeaconCommittee
+}
+
+func (f *fakeCommitteesProvider) BeaconCommittees(_ context.Context, _ string) ([]*apiv1.BeaconCommittee, error) {
+	return f.committees, nil
+}
+
+// TestScoreElectraBeaconBlockProposalPerCommitteeDedup confirms that, with a committees provider
+// configured, two attestations covering overlapping-but-different committee sets only credit a
+// validator in the shared committee once, even though neither attestation's committee set is an
+// exact match for the other's.
+func TestScoreElectraBeaconBlockProposalPerCommitteeDedup(t *testing.T) {
+	// Committee 1 has 2 validators, committee 2 has 2 validators, committee 3 has 2 validators.
+	provider := &fakeCommitteesProvider{committees: []*apiv1.BeaconCommittee{
+		{Index: 1, Validators: []phase0.ValidatorIndex{10, 11}},
+		{Index: 2, Validators: []phase0.ValidatorIndex{20, 21}},
+		{Index: 3, Validators: []phase0.ValidatorIndex{30, 31}},
+	}}
+
+	// First attestation covers committees {1,2}, with both members of each voting.
+	firstCommitteeBits := bitfield.Bitvector64{}
+	firstCommitteeBits.SetBitAt(1, true)
+	firstCommitteeBits.SetBitAt(2, true)
+	firstBits := bitfield.NewBitlist(4)
+	firstBits.SetBitAt(0, true)
+	firstBits.SetBitAt(1, true)
+	firstBits.SetBitAt(2, true)
+	firstBits.SetBitAt(3, true)
+
+	// Second attestation covers committees {2,3}: committee 2 is shared with the first
+	// attestation and fully resubmitted, committee 3 is new.
+	secondCommitteeBits := bitfield.Bitvector64{}
+	secondCommitteeBits.SetBitAt(2, true)
+	secondCommitteeBits.SetBitAt(3, true)
+	secondBits := bitfield.NewBitlist(4)
+	secondBits.SetBitAt(0, true)
+	secondBits.SetBitAt(1, true)
+	secondBits.SetBitAt(2, true)
+	secondBits.SetBitAt(3, true)
+
+	block := &electra.BeaconBlock{
+		Body: &electra.BeaconBlockBody{
+			Attestations: []*electra.Attestation{
+				{
+					AggregationBits: firstBits,
+					CommitteeBits:   firstCommitteeBits,
+					Data:            &phase0.AttestationData{Slot: phase0.Slot(10)},
+				},
+				{
+					AggregationBits: secondBits,
+					CommitteeBits:   secondCommitteeBits,
+					Data:            &phase0.AttestationData{Slot: phase0.Slot(10)},
+				},
+			},
+		},
+	}
+
+	// At slot 11, inclusion distance is 1 for both attestations. The first attestation credits 4
+	// new participants (committees 1 and 2, both full). The second attestation's committee 2 is
+	// entirely already credited, so only committee 3's 2 members are new: 2 new participants.
+	// Total new participants across the block: 6, doubled for the immediate-attestation bonus.
+	n := NewNaive(WithCommitteesProvider(provider))
+	got := n.scoreElectraBeaconBlockProposal(context.Background(), "test", 11, block)
+	require.InDelta(t, 12.0, got, 0.0001)
+}
@@ -0,0 +1,376 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+)
+
+// slotsPerEpoch is the number of slots in an epoch on mainnet-configured networks.
+const slotsPerEpoch = 32
+
+// epochRetention is how many epochs of effective balance and committee data are kept before
+// being evicted as no-longer-scorable: attestations cannot be included more than one epoch late,
+// so anything older than this is never looked up again.
+const epochRetention = 2
+
+// maxCanonicalRootLookback bounds how far back votesMatchCanonicalChain will walk looking for a
+// non-skipped slot when resolving a canonical block root, so that a long run of skipped slots
+// cannot turn this into an unbounded scan.
+const maxCanonicalRootLookback = 2048
+
+// Reward-flag decay weights, reflecting how a vote's value to finality decays the further it is
+// from the canonical chain: a correct source vote is always rewarded, a correct target vote is
+// worth half as much again, and a correct head vote an eighth.
+const (
+	sourceWeight = 1.0
+	targetWeight = 1.0 / 2.0
+	headWeight   = 1.0 / 8.0
+)
+
+// RewardAware scores a beacon block proposal against a model closer to actual consensus rewards
+// than Naive's flat 1/inclusion_distance heuristic: it weights each attestation's contribution
+// by whether its target and head votes match the canonical chain, by the participant's effective
+// balance, and by the source/target/head reward-flag decay used by the spec.
+type RewardAware struct {
+	beaconBlockRootProvider  eth2client.BeaconBlockRootProvider
+	validatorsProvider       eth2client.ValidatorsProvider
+	beaconCommitteesProvider eth2client.BeaconCommitteesProvider
+
+	mu                sync.Mutex
+	effectiveBalances map[phase0.Epoch]map[phase0.ValidatorIndex]phase0.Gwei
+	committees        map[phase0.Slot]map[phase0.CommitteeIndex][]phase0.ValidatorIndex
+}
+
+// NewRewardAware creates a new reward-aware scorer. The beacon committees provider is used to
+// resolve an attestation's aggregation bits to validator indices, which the duties service would
+// otherwise have to supply.
+func NewRewardAware(beaconBlockRootProvider eth2client.BeaconBlockRootProvider, validatorsProvider eth2client.ValidatorsProvider, beaconCommitteesProvider eth2client.BeaconCommitteesProvider) (*RewardAware, error) {
+	if beaconBlockRootProvider == nil {
+		return nil, errors.New("no beacon block root provider specified")
+	}
+	if validatorsProvider == nil {
+		return nil, errors.New("no validators provider specified")
+	}
+	if beaconCommitteesProvider == nil {
+		return nil, errors.New("no beacon committees provider specified")
+	}
+
+	return &RewardAware{
+		beaconBlockRootProvider:  beaconBlockRootProvider,
+		validatorsProvider:       validatorsProvider,
+		beaconCommitteesProvider: beaconCommitteesProvider,
+		effectiveBalances:        make(map[phase0.Epoch]map[phase0.ValidatorIndex]phase0.Gwei),
+		committees:               make(map[phase0.Slot]map[phase0.CommitteeIndex][]phase0.ValidatorIndex),
+	}, nil
+}
+
+// Score generates a reward-aware score for a beacon block, summing each of its attestations'
+// weighted contribution. Electra's multi-committee attestations (EIP-7549) are not scored here:
+// for Electra blocks only slashings contribute (see attestationsForVersion).
+//
+// Unlike Naive, this does not score post-Altair sync aggregate participation: resolving it to a
+// balance-weighted contribution needs the sync committee's member validator indices, which - like
+// the committee used for attestations - are state data the block alone does not carry, but unlike
+// the attester committee there is no provider wired here to fetch them. Score a block's sync
+// committee contribution with Naive until a sync committee member provider is added here, the
+// same way Electra attestations are scored with Naive today. A warning is logged whenever a
+// post-Altair block's sync aggregate is skipped, so an operator A/B testing scorers (see
+// best.WithScorer) isn't left guessing why reward-aware scores run systematically lower than
+// Naive's for sync-committee-heavy slots.
+func (s *RewardAware) Score(ctx context.Context, name string, slot uint64, block *VersionedBeaconBlock) float64 {
+	attestations, numProposerSlashings, numAttesterSlashings := attestationsForVersion(block)
+
+	score := s.scoreAttestations(ctx, name, slot, attestations)
+	score += s.scoreSlashings(numProposerSlashings, numAttesterSlashings)
+
+	warnIfSyncAggregateSkipped(name, slot, syncAggregateForVersion(block))
+
+	log.Trace().Uint64("slot", slot).Str("provider", name).Float64("total", score).Msg("Scored block (reward-aware)")
+
+	return score
+}
+
+// ScoreBlinded generates a reward-aware score for a blinded beacon block proposal, on the same
+// basis as Score, so that a builder's bid can be compared directly against a local block's
+// score. Blinded proposals carry the same phase0-style attestation format as a Bellatrix-or-later
+// full block.
+func (s *RewardAware) ScoreBlinded(ctx context.Context, name string, slot uint64, block *apiv1.BlindedBeaconBlock) float64 {
+	body := block.Body
+
+	score := s.scoreAttestations(ctx, name, slot, body.Attestations)
+	score += s.scoreSlashings(len(body.ProposerSlashings), len(body.AttesterSlashings))
+
+	warnIfSyncAggregateSkipped(name, slot, body.SyncAggregate)
+
+	log.Trace().Uint64("slot", slot).Str("provider", name).Float64("total", score).Msg("Scored blinded block (reward-aware)")
+
+	return score
+}
+
+// warnIfSyncAggregateSkipped logs a warning, each time it is given a post-Altair block's sync
+// aggregate, that its participation was not scored, rather than silently dropping it from the
+// reward-aware score.
+func warnIfSyncAggregateSkipped(name string, slot uint64, syncAggregate *altair.SyncAggregate) {
+	if syncAggregate == nil {
+		return
+	}
+
+	log.Warn().
+		Uint64("slot", slot).
+		Str("provider", name).
+		Uint64("sync_committee_bits", syncAggregate.SyncCommitteeBits.Count()).
+		Msg("Skipping sync aggregate scoring; reward-aware has no sync committee member provider wired")
+}
+
+// scoreAttestations sums the reward-aware weighted contribution of each attestation: its
+// participants' effective balances, weighted by whether the attestation's target and head votes
+// match the canonical chain.
+func (s *RewardAware) scoreAttestations(ctx context.Context, name string, slot uint64, attestations []*phase0.Attestation) float64 {
+	score := float64(0)
+	for _, attestation := range attestations {
+		targetMatches, headMatches, err := s.votesMatchCanonicalChain(ctx, attestation.Data)
+		if err != nil {
+			log.Warn().Err(err).Uint64("slot", slot).Str("provider", name).Msg("Failed to establish canonical chain match for attestation")
+			continue
+		}
+
+		epoch := phase0.Epoch(attestation.Data.Slot / slotsPerEpoch)
+		for _, validatorIndex := range s.attestingValidatorIndices(ctx, attestation) {
+			balance, err := s.effectiveBalance(ctx, epoch, validatorIndex)
+			if err != nil {
+				log.Warn().Err(err).Uint64("validator_index", uint64(validatorIndex)).Msg("Failed to obtain effective balance")
+				continue
+			}
+
+			// Source is always rewarded if the attestation was included at all; target and head
+			// are only rewarded if they match the canonical chain at the relevant checkpoint.
+			weight := sourceWeight
+			if targetMatches {
+				weight += targetWeight
+			}
+			if headMatches {
+				weight += headWeight
+			}
+
+			score += weight * float64(balance)
+		}
+	}
+
+	return score
+}
+
+// scoreSlashings weights slashings as in the naive scorer; the whistleblower/proposer reward for
+// a slashing is not meaningfully affected by the reward-aware participation model.
+func (s *RewardAware) scoreSlashings(numProposerSlashings int, numAttesterSlashings int) float64 {
+	slashingWeight := float64(700)
+	return float64(numProposerSlashings)*slashingWeight + float64(numAttesterSlashings)*slashingWeight
+}
+
+// votesMatchCanonicalChain establishes whether an attestation's target and head votes match the
+// block roots this node considers canonical at the relevant slots.
+func (s *RewardAware) votesMatchCanonicalChain(ctx context.Context, data *phase0.AttestationData) (targetMatches bool, headMatches bool, err error) {
+	targetRoot, err := s.canonicalBlockRoot(ctx, uint64(data.Target.Epoch)*slotsPerEpoch)
+	if err != nil {
+		return false, false, errors.Wrap(err, "failed to obtain canonical target root")
+	}
+	headRoot, err := s.canonicalBlockRoot(ctx, uint64(data.Slot))
+	if err != nil {
+		return false, false, errors.Wrap(err, "failed to obtain canonical head root")
+	}
+
+	return targetRoot == data.Target.Root, headRoot == data.BeaconBlockRoot, nil
+}
+
+// canonicalBlockRoot resolves the canonical block root as of the given slot. If that slot was
+// skipped - the ordinary case when a target epoch's boundary slot is empty - it walks backwards,
+// slot by slot, until it finds a block, capped at maxCanonicalRootLookback iterations. Without
+// this walk-back, any skipped boundary slot would fail the whole attestation's target/head
+// lookup rather than just failing to match.
+func (s *RewardAware) canonicalBlockRoot(ctx context.Context, slot uint64) (phase0.Root, error) {
+	for attempts := 0; attempts < maxCanonicalRootLookback; attempts++ {
+		root, err := s.beaconBlockRootProvider.BeaconBlockRoot(ctx, fmt.Sprintf("%d", slot))
+		if err == nil {
+			return *root, nil
+		}
+		if slot == 0 {
+			break
+		}
+		slot--
+	}
+
+	return phase0.Root{}, errors.New("failed to find canonical block root within lookback window")
+}
+
+// effectiveBalance returns the effective balance of a validator as of the given epoch, fetching
+// and caching it the first time it is requested for that epoch. The network call runs without
+// the lock held, so that concurrent Score calls - one per beacon node/builder - are not
+// serialized behind a single synchronous round-trip.
+func (s *RewardAware) effectiveBalance(ctx context.Context, epoch phase0.Epoch, validatorIndex phase0.ValidatorIndex) (phase0.Gwei, error) {
+	s.mu.Lock()
+	byValidator, exists := s.effectiveBalances[epoch]
+	if exists {
+		if balance, exists := byValidator[validatorIndex]; exists {
+			s.mu.Unlock()
+			return balance, nil
+		}
+	}
+	s.mu.Unlock()
+
+	validators, err := s.validatorsProvider.Validators(ctx, fmt.Sprintf("%d", uint64(epoch)*slotsPerEpoch), []phase0.ValidatorIndex{validatorIndex})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to obtain validator")
+	}
+	validator, exists := validators[validatorIndex]
+	if !exists {
+		return 0, errors.New("validator not known at epoch")
+	}
+	balance := validator.Validator.EffectiveBalance
+
+	s.mu.Lock()
+	byValidator, exists = s.effectiveBalances[epoch]
+	if !exists {
+		byValidator = make(map[phase0.ValidatorIndex]phase0.Gwei)
+		s.effectiveBalances[epoch] = byValidator
+	}
+	byValidator[validatorIndex] = balance
+	// Attestations cannot be included more than one epoch late, so entries older than
+	// epochRetention epochs will never be looked up again; drop them so the cache does not grow
+	// without bound across a long-running process.
+	for cachedEpoch := range s.effectiveBalances {
+		if cachedEpoch+epochRetention < epoch {
+			delete(s.effectiveBalances, cachedEpoch)
+		}
+	}
+	s.mu.Unlock()
+
+	return balance, nil
+}
+
+// syncAggregateForVersion extracts a post-Altair block's sync aggregate, so Score can log that it
+// is being skipped. Phase0 blocks have no sync aggregate and return nil.
+func syncAggregateForVersion(block *VersionedBeaconBlock) *altair.SyncAggregate {
+	switch block.Version {
+	case consensusspec.DataVersionDeneb:
+		return block.Deneb.Body.SyncAggregate
+	case consensusspec.DataVersionCapella:
+		return block.Capella.Body.SyncAggregate
+	case consensusspec.DataVersionBellatrix:
+		return block.Bellatrix.Body.SyncAggregate
+	case consensusspec.DataVersionAltair:
+		return block.Altair.Body.SyncAggregate
+	case consensusspec.DataVersionElectra:
+		return block.Electra.Body.SyncAggregate
+	default:
+		return nil
+	}
+}
+
+// attestationsForVersion extracts the phase0-format attestations and slashing counts from a
+// block. Electra's multi-committee attestations (EIP-7549) are not yet supported by the
+// reward-aware model, which needs a single (slot, committee index) pair to resolve validator
+// indices; for those blocks only slashings are scored here. Score Electra proposals with Naive
+// until this is extended to EIP-7549's per-committee aggregation bits.
+func attestationsForVersion(block *VersionedBeaconBlock) ([]*phase0.Attestation, int, int) {
+	switch block.Version {
+	case consensusspec.DataVersionDeneb:
+		body := block.Deneb.Body
+		return body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)
+	case consensusspec.DataVersionCapella:
+		body := block.Capella.Body
+		return body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)
+	case consensusspec.DataVersionBellatrix:
+		body := block.Bellatrix.Body
+		return body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)
+	case consensusspec.DataVersionAltair:
+		body := block.Altair.Body
+		return body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)
+	case consensusspec.DataVersionElectra:
+		body := block.Electra.Body
+		return nil, len(body.ProposerSlashings), len(body.AttesterSlashings)
+	default:
+		body := block.Phase0.Body
+		return body.Attestations, len(body.ProposerSlashings), len(body.AttesterSlashings)
+	}
+}
+
+// attestingValidatorIndices resolves an attestation's aggregation bits to validator indices via
+// the beacon committee assigned to its (slot, committee index), caching the committee lookup per
+// slot so that many attestations in the same block only fetch it once.
+func (s *RewardAware) attestingValidatorIndices(ctx context.Context, attestation *phase0.Attestation) []phase0.ValidatorIndex {
+	committee, err := s.committeeValidators(ctx, attestation.Data.Slot, attestation.Data.Index)
+	if err != nil {
+		log.Warn().Err(err).Uint64("slot", uint64(attestation.Data.Slot)).Msg("Failed to obtain committee validators")
+		return nil
+	}
+
+	indices := make([]phase0.ValidatorIndex, 0, attestation.AggregationBits.Count())
+	for i := uint64(0); i < attestation.AggregationBits.Len(); i++ {
+		if !attestation.AggregationBits.BitAt(i) {
+			continue
+		}
+		if i >= uint64(len(committee)) {
+			log.Warn().Uint64("bit", i).Int("committee_size", len(committee)).Msg("Aggregation bit has no corresponding committee member")
+			continue
+		}
+		indices = append(indices, committee[i])
+	}
+
+	return indices
+}
+
+// committeeValidators returns the validator indices of the beacon committee for a (slot,
+// committee index) pair, fetching and caching the full set of committees for the slot the first
+// time any of its committees is requested.
+func (s *RewardAware) committeeValidators(ctx context.Context, slot phase0.Slot, committeeIndex phase0.CommitteeIndex) ([]phase0.ValidatorIndex, error) {
+	s.mu.Lock()
+	bySlot, exists := s.committees[slot]
+	s.mu.Unlock()
+
+	if !exists {
+		committees, err := s.beaconCommitteesProvider.BeaconCommittees(ctx, fmt.Sprintf("%d", slot))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to obtain beacon committees")
+		}
+
+		bySlot = make(map[phase0.CommitteeIndex][]phase0.ValidatorIndex, len(committees))
+		for _, committee := range committees {
+			bySlot[committee.Index] = committee.Validators
+		}
+
+		epoch := phase0.Epoch(slot / slotsPerEpoch)
+		s.mu.Lock()
+		s.committees[slot] = bySlot
+		// Attestations cannot be included more than one epoch late, so committees for slots older
+		// than epochRetention epochs will never be looked up again; drop them so the cache does not
+		// grow without bound across a long-running process.
+		for cachedSlot := range s.committees {
+			if phase0.Epoch(cachedSlot/slotsPerEpoch)+epochRetention < epoch {
+				delete(s.committees, cachedSlot)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return bySlot[committeeIndex], nil
+}
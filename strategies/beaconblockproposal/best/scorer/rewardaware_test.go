@@ -0,0 +1,133 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBeaconBlockRootProvider returns a canned root for a set of "populated" slots and an error
+// for every other (skipped) slot, so canonicalBlockRoot's walk-back can be exercised.
+type fakeBeaconBlockRootProvider struct {
+	roots map[string]phase0.Root
+}
+
+func (f *fakeBeaconBlockRootProvider) BeaconBlockRoot(_ context.Context, stateID string) (*phase0.Root, error) {
+	root, exists := f.roots[stateID]
+	if !exists {
+		return nil, errors.New("slot not found (skipped)")
+	}
+	return &root, nil
+}
+
+// TestCanonicalBlockRootWalksBackOverSkippedSlots confirms that a skipped boundary slot does not
+// fail the lookup outright; it should walk back to the last non-skipped slot, the same technique
+// dutycache.go's dependentRoot uses for attester duty cache invalidation.
+func TestCanonicalBlockRootWalksBackOverSkippedSlots(t *testing.T) {
+	want := phase0.Root{0x01}
+	provider := &fakeBeaconBlockRootProvider{roots: map[string]phase0.Root{
+		fmt.Sprintf("%d", 97): want,
+	}}
+	s := &RewardAware{beaconBlockRootProvider: provider}
+
+	got, err := s.canonicalBlockRoot(context.Background(), 100)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+// TestCanonicalBlockRootLookbackExhausted confirms the walk-back gives up with an error rather
+// than scanning forever when no non-skipped slot is found.
+func TestCanonicalBlockRootLookbackExhausted(t *testing.T) {
+	provider := &fakeBeaconBlockRootProvider{roots: map[string]phase0.Root{}}
+	s := &RewardAware{beaconBlockRootProvider: provider}
+
+	_, err := s.canonicalBlockRoot(context.Background(), maxCanonicalRootLookback+10)
+	require.Error(t, err)
+}
+
+// fakeValidatorsProvider returns the same effective balance for every validator requested.
+type fakeValidatorsProvider struct {
+	balance phase0.Gwei
+}
+
+func (f *fakeValidatorsProvider) Validators(_ context.Context, _ string, indices []phase0.ValidatorIndex) (map[phase0.ValidatorIndex]*apiv1.Validator, error) {
+	validators := make(map[phase0.ValidatorIndex]*apiv1.Validator, len(indices))
+	for _, index := range indices {
+		validators[index] = &apiv1.Validator{
+			Index:     index,
+			Validator: &phase0.Validator{EffectiveBalance: f.balance},
+		}
+	}
+	return validators, nil
+}
+
+// TestEffectiveBalanceEvictsOldEpochs confirms that once a later epoch's effective balance is
+// resolved, entries older than epochRetention are dropped rather than kept forever.
+func TestEffectiveBalanceEvictsOldEpochs(t *testing.T) {
+	s := &RewardAware{
+		validatorsProvider: &fakeValidatorsProvider{balance: phase0.Gwei(32000000000)},
+		effectiveBalances:  make(map[phase0.Epoch]map[phase0.ValidatorIndex]phase0.Gwei),
+	}
+
+	_, err := s.effectiveBalance(context.Background(), phase0.Epoch(1), phase0.ValidatorIndex(0))
+	require.NoError(t, err)
+	_, exists := s.effectiveBalances[phase0.Epoch(1)]
+	require.True(t, exists)
+
+	_, err = s.effectiveBalance(context.Background(), phase0.Epoch(1+epochRetention+1), phase0.ValidatorIndex(0))
+	require.NoError(t, err)
+
+	_, exists = s.effectiveBalances[phase0.Epoch(1)]
+	require.False(t, exists, "effective balance for an epoch too old to ever be scored again should have been evicted")
+}
+
+// TestSyncAggregateForVersionBellatrix confirms that a post-Altair block's sync aggregate is
+// extracted so that warnIfSyncAggregateSkipped has something to report on; RewardAware.Score
+// never scores it.
+func TestSyncAggregateForVersionBellatrix(t *testing.T) {
+	bits := bitfield.Bitvector64{}
+	bits.SetBitAt(0, true)
+	bits.SetBitAt(1, true)
+	syncAggregate := &altair.SyncAggregate{SyncCommitteeBits: bits}
+	block := &VersionedBeaconBlock{
+		Version: consensusspec.DataVersionBellatrix,
+		Bellatrix: &bellatrix.BeaconBlock{
+			Body: &bellatrix.BeaconBlockBody{SyncAggregate: syncAggregate},
+		},
+	}
+
+	require.Same(t, syncAggregate, syncAggregateForVersion(block))
+}
+
+// TestSyncAggregateForVersionPhase0 confirms that a Phase0 block, which predates sync
+// committees, reports no sync aggregate to warn about.
+func TestSyncAggregateForVersionPhase0(t *testing.T) {
+	block := &VersionedBeaconBlock{
+		Version: consensusspec.DataVersionPhase0,
+		Phase0:  &phase0.BeaconBlock{Body: &phase0.BeaconBlockBody{}},
+	}
+
+	require.Nil(t, syncAggregateForVersion(block))
+}
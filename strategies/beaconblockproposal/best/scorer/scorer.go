@@ -0,0 +1,37 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scorer provides pluggable backends for scoring beacon block proposals, so that the
+// best strategy can pick the highest-reward proposal from a number of beacon nodes. Operators
+// can select a backend with best.WithScorer(), allowing a naive heuristic to be A/B tested
+// against a reward-aware implementation.
+package scorer
+
+import (
+	"context"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+)
+
+// Scorer generates a reward-relative score for a beacon block proposal. Higher is better; scores
+// from different proposals for the same slot - whether full or blinded - are directly
+// comparable, so that the best strategy can weigh a builder's bid against a local block on a
+// common reward basis.
+type Scorer interface {
+	// Score returns a score for the given proposal, relative to the reward expected from
+	// proposing it.
+	Score(ctx context.Context, name string, slot uint64, block *VersionedBeaconBlock) float64
+	// ScoreBlinded returns a score for the given blinded proposal, on the same basis as Score,
+	// so that a builder's bid can be compared directly against a local block's score.
+	ScoreBlinded(ctx context.Context, name string, slot uint64, block *apiv1.BlindedBeaconBlock) float64
+}
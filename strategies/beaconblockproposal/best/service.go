@@ -19,8 +19,9 @@ import (
 	"time"
 
 	eth2client "github.com/attestantio/go-eth2-client"
-	spec "github.com/attestantio/go-eth2-client/spec/phase0"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/vouch/services/metrics"
+	"github.com/attestantio/vouch/strategies/beaconblockproposal/best/scorer"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
@@ -29,10 +30,22 @@ import (
 
 // Service is the provider for beacon block proposals.
 type Service struct {
-	clientMonitor                metrics.ClientMonitor
-	processConcurrency           int64
-	beaconBlockProposalProviders map[string]eth2client.BeaconBlockProposalProvider
-	timeout                      time.Duration
+	clientMonitor                       metrics.ClientMonitor
+	processConcurrency                  int64
+	beaconBlockProposalProviders        map[string]eth2client.BeaconBlockProposalProvider
+	blindedBeaconBlockProposalProviders map[string]eth2client.BlindedBeaconBlockProposalProvider
+	timeout                             time.Duration
+	minBidDelta                         float64
+	scorer                              scorer.Scorer
+}
+
+// Proposal is the result of a best-of-many beacon block proposal fetch, tagged with whether
+// the winner came from an MEV builder (blinded) or a local full block.
+type Proposal struct {
+	Block        *scorer.VersionedBeaconBlock
+	BlindedBlock *apiv1.BlindedBeaconBlock
+	Blinded      bool
+	Score        float64
 }
 
 // module-wide log.
@@ -52,20 +65,25 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	s := &Service{
-		processConcurrency:           parameters.processConcurrency,
-		beaconBlockProposalProviders: parameters.beaconBlockProposalProviders,
-		timeout:                      parameters.timeout,
-		clientMonitor:                parameters.clientMonitor,
+		processConcurrency:                  parameters.processConcurrency,
+		beaconBlockProposalProviders:        parameters.beaconBlockProposalProviders,
+		blindedBeaconBlockProposalProviders: parameters.blindedBeaconBlockProposalProviders,
+		timeout:                             parameters.timeout,
+		clientMonitor:                       parameters.clientMonitor,
+		minBidDelta:                         parameters.minBidDelta,
+		scorer:                              parameters.scorer,
 	}
 
 	return s, nil
 }
 
-// BeaconBlockProposal provies the best beacon block proposal from a number of beacon nodes.
-func (s *Service) BeaconBlockProposal(ctx context.Context, slot uint64, randaoReveal []byte, graffiti []byte) (*spec.BeaconBlock, error) {
+// BeaconBlockProposal provides the best beacon block proposal from a number of beacon nodes and,
+// where configured, MEV builders. It queries both sets of providers concurrently, scores the
+// results on a common basis, and returns the winner tagged as blinded or full.
+func (s *Service) BeaconBlockProposal(ctx context.Context, slot uint64, randaoReveal []byte, graffiti []byte) (*Proposal, error) {
 	var mu sync.Mutex
-	bestScore := float64(0)
-	var bestProposal *spec.BeaconBlock
+	var bestFull *Proposal
+	var bestBlinded *Proposal
 
 	sem := semaphore.NewWeighted(s.processConcurrency)
 	var wg sync.WaitGroup
@@ -92,57 +110,123 @@ func (s *Service) BeaconBlockProposal(ctx context.Context, slot uint64, randaoRe
 			log.Trace().Dur("elapsed", time.Since(started)).Msg("Obtained beacon block proposal")
 			cancel()
 
+			versioned := scorer.NewVersionedBeaconBlock(proposal)
+			score := s.scorer.Score(ctx, name, slot, versioned)
+
+			mu.Lock()
+			if bestFull == nil || score > bestFull.Score {
+				bestFull = &Proposal{Block: versioned, Score: score}
+			}
+			mu.Unlock()
+		}(ctx, sem, &wg, name, provider, &mu)
+	}
+	for name, provider := range s.blindedBeaconBlockProposalProviders {
+		wg.Add(1)
+		go func(ctx context.Context, sem *semaphore.Weighted, wg *sync.WaitGroup, name string, provider eth2client.BlindedBeaconBlockProposalProvider, mu *sync.Mutex) {
+			defer wg.Done()
+
+			if err := sem.Acquire(ctx, 1); err != nil {
+				log.Error().Err(err).Msg("Failed to acquire semaphore")
+				return
+			}
+			log := log.With().Str("provider", name).Uint64("slot", slot).Logger()
+
+			opCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			started := time.Now()
+			blindedProposal, err := provider.BlindedBeaconBlockProposal(opCtx, slot, randaoReveal, graffiti)
+			s.clientMonitor.ClientOperation(name, "blinded beacon block proposal", err == nil, time.Since(started))
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to obtain blinded beacon block proposal")
+				cancel()
+				return
+			}
+			log.Trace().Dur("elapsed", time.Since(started)).Msg("Obtained blinded beacon block proposal")
+			cancel()
+
+			score := s.scorer.ScoreBlinded(ctx, name, slot, blindedProposal)
+
 			mu.Lock()
-			score := scoreBeaconBlockProposal(ctx, name, slot, proposal)
-			if score > bestScore || bestProposal == nil {
-				bestScore = score
-				bestProposal = proposal
+			if bestBlinded == nil || score > bestBlinded.Score {
+				bestBlinded = &Proposal{BlindedBlock: blindedProposal, Blinded: true, Score: score}
 			}
 			mu.Unlock()
 		}(ctx, sem, &wg, name, provider, &mu)
 	}
 	wg.Wait()
 
-	return bestProposal, nil
-}
+	if bestFull == nil && bestBlinded == nil {
+		return nil, errors.New("no beacon block proposals received")
+	}
 
-// scoreBeaconBlockPropsal generates a score for a beacon block.
-// The score is relative to the reward expected by proposing the block.
-func scoreBeaconBlockProposal(ctx context.Context, name string, slot uint64, blockProposal *spec.BeaconBlock) float64 {
-	immediateAttestationScore := float64(0)
-	attestationScore := float64(0)
-
-	// Add attestation scores.
-	for _, attestation := range blockProposal.Body.Attestations {
-		inclusionDistance := float64(slot - attestation.Data.Slot)
-		attestationScore += float64(attestation.AggregationBits.Count()) / inclusionDistance
-		if inclusionDistance == 1 {
-			immediateAttestationScore += float64(attestation.AggregationBits.Count()) / inclusionDistance
+	// A blinded bid must beat the best full block by at least minBidDelta to be worth the extra
+	// risk of a builder that times out or submits an invalid signed header. This comparison is
+	// made exactly once, between the best of each kind, rather than as each bid is gathered.
+	best := bestFull
+	if bestBlinded != nil && (bestFull == nil || bestBlinded.Score > bestFull.Score+s.minBidDelta) {
+		best = bestBlinded
+	}
+
+	if best.Blinded {
+		if err := s.validateBlindedProposal(ctx, best); err != nil {
+			log.Warn().Err(err).Msg("Blinded proposal failed validation; falling back to best full block")
+			return s.fallbackToFullProposal(ctx, slot, randaoReveal, graffiti)
 		}
 	}
 
-	// Add slashing scores.
-	// Slashing reward will be at most MAX_EFFECTIVE_BALANCE/WHISTLEBLOWER_REWARD_QUOTIENT,
-	// which is 0.0625 Ether.
-	// Individual attestation reward at 16K validators will be around 90,000 GWei, or .00009 Ether.
-	// So we state that a single slashing event has the same weight as about 700 attestations.
-	slashingWeight := float64(700)
-
-	// Add proposer slashing scores.
-	proposerSlashingScore := float64(len(blockProposal.Body.ProposerSlashings)) * slashingWeight
-
-	// Add attester slashing scores.
-	attesterSlashingScore := float64(len(blockProposal.Body.AttesterSlashings)) * slashingWeight
-
-	log.Trace().
-		Uint64("slot", slot).
-		Str("provider", name).
-		Float64("immediate_attestations", immediateAttestationScore).
-		Float64("attestations", attestationScore).
-		Float64("proposer_slashings", proposerSlashingScore).
-		Float64("attester_slashings", attesterSlashingScore).
-		Float64("total", attestationScore+proposerSlashingScore+attesterSlashingScore).
-		Msg("Scored block")
-
-	return attestationScore + proposerSlashingScore + attestationScore
-}
\ No newline at end of file
+	return best, nil
+}
+
+// fallbackToFullProposal is invoked when the winning blinded proposal's header fails
+// validateBlindedProposal. It re-runs the local-only path and returns the best full block
+// available. It is not invoked for anything that happens to a blinded proposal after it has been
+// returned, such as a failed reveal or submission.
+func (s *Service) fallbackToFullProposal(ctx context.Context, slot uint64, randaoReveal []byte, graffiti []byte) (*Proposal, error) {
+	log.Warn().Uint64("slot", slot).Msg("Falling back to local full block")
+
+	var mu sync.Mutex
+	var best *Proposal
+
+	sem := semaphore.NewWeighted(s.processConcurrency)
+	var wg sync.WaitGroup
+	for name, provider := range s.beaconBlockProposalProviders {
+		wg.Add(1)
+		go func(ctx context.Context, sem *semaphore.Weighted, wg *sync.WaitGroup, name string, provider eth2client.BeaconBlockProposalProvider, mu *sync.Mutex) {
+			defer wg.Done()
+			if err := sem.Acquire(ctx, 1); err != nil {
+				log.Error().Err(err).Msg("Failed to acquire semaphore")
+				return
+			}
+			opCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			proposal, err := provider.BeaconBlockProposal(opCtx, slot, randaoReveal, graffiti)
+			if err != nil {
+				log.Warn().Err(err).Str("provider", name).Msg("Failed to obtain beacon block proposal")
+				return
+			}
+			versioned := scorer.NewVersionedBeaconBlock(proposal)
+			score := s.scorer.Score(ctx, name, slot, versioned)
+			mu.Lock()
+			if best == nil || score > best.Score {
+				best = &Proposal{Block: versioned, Score: score}
+			}
+			mu.Unlock()
+		}(ctx, sem, &wg, name, provider, &mu)
+	}
+	wg.Wait()
+
+	if best == nil {
+		return nil, errors.New("no beacon block proposals received for fallback")
+	}
+
+	return best, nil
+}
+
+// validateBlindedProposal confirms that the winning blinded header is well-formed before it is
+// handed off for signing and submission.
+func (s *Service) validateBlindedProposal(ctx context.Context, proposal *Proposal) error {
+	if proposal.BlindedBlock == nil || proposal.BlindedBlock.Body == nil || proposal.BlindedBlock.Body.ExecutionPayloadHeader == nil {
+		return errors.New("blinded proposal missing execution payload header")
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright © 2021 Attestant Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package best
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/attestantio/vouch/strategies/beaconblockproposal/best/scorer"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClientMonitor is a no-op metrics.ClientMonitor for use in tests that don't care about
+// monitoring output.
+type fakeClientMonitor struct{}
+
+func (*fakeClientMonitor) ClientOperation(_ string, _ string, _ bool, _ time.Duration) {}
+
+// fakeBeaconBlockProposalProvider returns a canned full block proposal for a named provider.
+type fakeBeaconBlockProposalProvider struct{}
+
+func (*fakeBeaconBlockProposalProvider) BeaconBlockProposal(_ context.Context, _ uint64, _ []byte, _ []byte) (*spec.VersionedBeaconBlock, error) {
+	return &spec.VersionedBeaconBlock{
+		Version: spec.DataVersionPhase0,
+		Phase0:  &phase0.BeaconBlock{},
+	}, nil
+}
+
+// fakeBlindedBeaconBlockProposalProvider returns a canned blinded proposal, optionally without an
+// execution payload header so that validateBlindedProposal rejects it.
+type fakeBlindedBeaconBlockProposalProvider struct {
+	valid bool
+}
+
+func (f *fakeBlindedBeaconBlockProposalProvider) BlindedBeaconBlockProposal(_ context.Context, _ uint64, _ []byte, _ []byte) (*apiv1.BlindedBeaconBlock, error) {
+	block := &apiv1.BlindedBeaconBlock{Body: &apiv1.BlindedBeaconBlockBody{}}
+	if f.valid {
+		block.Body.ExecutionPayloadHeader = &bellatrix.ExecutionPayloadHeader{}
+	}
+	return block, nil
+}
+
+// fakeScorer scores full and blinded proposals according to a fixed, provider-keyed lookup table,
+// so tests can exercise selection logic without depending on real scoring math.
+type fakeScorer struct {
+	scores map[string]float64
+}
+
+func (f *fakeScorer) Score(_ context.Context, name string, _ uint64, _ *scorer.VersionedBeaconBlock) float64 {
+	return f.scores[name]
+}
+
+func (f *fakeScorer) ScoreBlinded(_ context.Context, name string, _ uint64, _ *apiv1.BlindedBeaconBlock) float64 {
+	return f.scores[name]
+}
+
+// TestBeaconBlockProposalMultipleBlindedBids confirms that when several blinded bids compete,
+// minBidDelta is applied once between the best blinded bid and the best full block, rather than
+// against whichever blinded bid's goroutine happens to update the shared best first.
+func TestBeaconBlockProposalMultipleBlindedBids(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(ctx,
+		WithClientMonitor(&fakeClientMonitor{}),
+		WithProcessConcurrency(4),
+		WithBeaconBlockProposalProviders(map[string]eth2client.BeaconBlockProposalProvider{
+			"full": &fakeBeaconBlockProposalProvider{},
+		}),
+		WithBlindedBeaconBlockProposalProviders(map[string]eth2client.BlindedBeaconBlockProposalProvider{
+			"builder1": &fakeBlindedBeaconBlockProposalProvider{valid: true},
+			"builder2": &fakeBlindedBeaconBlockProposalProvider{valid: true},
+			"builder3": &fakeBlindedBeaconBlockProposalProvider{valid: true},
+		}),
+		WithMinBidDelta(0.1),
+		WithScorer(&fakeScorer{scores: map[string]float64{
+			"full":     9,
+			"builder1": 10,
+			"builder2": 10.05,
+			"builder3": 10.1,
+		}}),
+	)
+	require.NoError(t, err)
+
+	proposal, err := s.BeaconBlockProposal(ctx, 1, nil, nil)
+	require.NoError(t, err)
+	require.True(t, proposal.Blinded)
+	require.InDelta(t, 10.1, proposal.Score, 0.0001)
+}
+
+// TestBeaconBlockProposalMinBidDeltaKeepsFullBlock confirms that a blinded bid which does not beat
+// the best full block by at least minBidDelta is not selected.
+func TestBeaconBlockProposalMinBidDeltaKeepsFullBlock(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(ctx,
+		WithClientMonitor(&fakeClientMonitor{}),
+		WithProcessConcurrency(4),
+		WithBeaconBlockProposalProviders(map[string]eth2client.BeaconBlockProposalProvider{
+			"full": &fakeBeaconBlockProposalProvider{},
+		}),
+		WithBlindedBeaconBlockProposalProviders(map[string]eth2client.BlindedBeaconBlockProposalProvider{
+			"builder1": &fakeBlindedBeaconBlockProposalProvider{valid: true},
+		}),
+		WithMinBidDelta(0.5),
+		WithScorer(&fakeScorer{scores: map[string]float64{
+			"full":     10,
+			"builder1": 10.2,
+		}}),
+	)
+	require.NoError(t, err)
+
+	proposal, err := s.BeaconBlockProposal(ctx, 1, nil, nil)
+	require.NoError(t, err)
+	require.False(t, proposal.Blinded)
+	require.InDelta(t, 10, proposal.Score, 0.0001)
+}
+
+// TestBeaconBlockProposalBlindedWinFallsBackOnValidationFailure confirms that a blinded bid which
+// wins the score comparison but fails validateBlindedProposal causes a fall back to the best
+// available full block rather than being returned as-is.
+func TestBeaconBlockProposalBlindedWinFallsBackOnValidationFailure(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(ctx,
+		WithClientMonitor(&fakeClientMonitor{}),
+		WithProcessConcurrency(4),
+		WithBeaconBlockProposalProviders(map[string]eth2client.BeaconBlockProposalProvider{
+			"full": &fakeBeaconBlockProposalProvider{},
+		}),
+		WithBlindedBeaconBlockProposalProviders(map[string]eth2client.BlindedBeaconBlockProposalProvider{
+			"builder1": &fakeBlindedBeaconBlockProposalProvider{valid: false},
+		}),
+		WithMinBidDelta(0),
+		WithScorer(&fakeScorer{scores: map[string]float64{
+			"full":     10,
+			"builder1": 20,
+		}}),
+	)
+	require.NoError(t, err)
+
+	proposal, err := s.BeaconBlockProposal(ctx, 1, nil, nil)
+	require.NoError(t, err)
+	require.False(t, proposal.Blinded)
+	require.InDelta(t, 10, proposal.Score, 0.0001)
+}